@@ -0,0 +1,132 @@
+package vsphere
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+)
+
+func dataSourceVSphereHostPortGroup() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "The name of the port group.",
+			Required:    true,
+		},
+		"host_system_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the host the port group is located on.",
+			Required:    true,
+		},
+		"datacenter_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the datacenter the host is located in. Used to disambiguate the network lookup when multiple datacenters have a network with the same name.",
+			Optional:    true,
+		},
+		"computed_policy": {
+			Type:        schema.TypeMap,
+			Description: "The effective network policy after inheritance. Note that this will look similar to, but is not the same, as the policy attributes defined in this resource.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"key": {
+			Type:        schema.TypeString,
+			Description: "The linkable identifier for this port group.",
+			Computed:    true,
+		},
+		"ports": {
+			Type:        schema.TypeList,
+			Description: "The ports that currently exist and are used on this port group.",
+			Computed:    true,
+			MaxItems:    1,
+			Elem:        portGroupPortSchema(),
+		},
+	}
+	structure.MergeSchema(s, schemaHostPortGroupSpec())
+
+	// This is a read-only data source: vswitch_name, vlan_id, active_nics,
+	// and standby_nics describe the port group being looked up, they are
+	// not inputs. Only name and host_system_id (defined above) identify
+	// which port group to read.
+	for _, field := range []string{"vswitch_name", "vlan_id", "active_nics", "standby_nics"} {
+		s[field].Required = false
+		s[field].Optional = false
+		s[field].Computed = true
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceVSphereHostPortGroupRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(defaultAPITimeout),
+		},
+		Schema: s,
+	}
+}
+
+func dataSourceVSphereHostPortGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	hsID := d.Get("host_system_id").(string)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	pg, err := hostPortGroupFromName(client, ns, name)
+	if err != nil {
+		return diag.Errorf("error fetching port group data: %s", err)
+	}
+
+	if err := flattenHostPortGroupSpec(d, &pg.Spec); err != nil {
+		return diag.Errorf("error setting resource data: %s", err)
+	}
+
+	if err := d.Set("key", pg.Key); err != nil {
+		return diag.Errorf("error setting key: %s", err)
+	}
+
+	var dc *object.Datacenter
+	if dcID, ok := d.GetOk("datacenter_id"); ok {
+		var err error
+		dc, err = datacenterFromID(client, dcID.(string))
+		if err != nil {
+			return diag.Errorf("cannot locate datacenter: %s", err)
+		}
+	}
+
+	finder := find.NewFinder(client.Client, false)
+	if dc != nil {
+		finder.SetDatacenter(dc)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
+	defer cancel()
+	networks, err := finder.NetworkList(ctx, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(networks) == 0 {
+		return diag.Errorf("%s %s not found", "Network", name)
+	}
+
+	d.SetId(networks[0].Reference().Value)
+	log.Printf("[DEBUG] Network ID is %s", networks[0].Reference().Value)
+
+	cpm, err := calculateComputedPolicy(pg.ComputedPolicy)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("computed_policy", cpm); err != nil {
+		return diag.Errorf("error saving effective policy to state: %s", err)
+	}
+	if err := d.Set("ports", calculatePorts(pg.Port)); err != nil {
+		return diag.Errorf("error setting port list: %s", err)
+	}
+
+	return nil
+}
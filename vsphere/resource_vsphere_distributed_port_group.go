@@ -0,0 +1,558 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourceVSphereDistributedPortGroup manages a DVS-backed port group
+// through DistributedVirtualSwitch.AddPortgroup, mirroring
+// resourceVSphereHostPortGroup for the standard vSwitch case. Reads
+// reconcile DVPortgroupConfigInfo into the same computed_policy map shape
+// as the host port group resource so the two can be swapped with minimal
+// HCL churn.
+func resourceVSphereDistributedPortGroup() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"distributed_virtual_switch_id": {
+			Type:        schema.TypeString,
+			Description: "The managed object ID of the DVS to attach this port group to.",
+			Required:    true,
+			ForceNew:    true,
+		},
+		"port_binding": {
+			Type:        schema.TypeString,
+			Description: "The port binding type for this port group. One of static, ephemeral, or dynamic.",
+			Optional:    true,
+			Default:     "static",
+		},
+		"auto_expand": {
+			Type:        schema.TypeBool,
+			Description: "Whether the port group should grow its port count automatically when ports are in use beyond the configured number_of_ports.",
+			Optional:    true,
+			Default:     true,
+		},
+		"number_of_ports": {
+			Type:        schema.TypeInt,
+			Description: "The number of ports in this port group.",
+			Optional:    true,
+			Default:     8,
+		},
+		"network_resource_pool_key": {
+			Type:        schema.TypeString,
+			Description: "The key of the network resource pool to associate this port group with.",
+			Optional:    true,
+		},
+		"vlan_range": {
+			Type:        schema.TypeList,
+			Description: "One or more VLAN trunk ranges to use for this port group.",
+			Optional:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"min_vlan": {
+						Type:        schema.TypeInt,
+						Description: "The minimum VLAN ID in this range.",
+						Required:    true,
+					},
+					"max_vlan": {
+						Type:        schema.TypeInt,
+						Description: "The maximum VLAN ID in this range.",
+						Required:    true,
+					},
+				},
+			},
+		},
+		"private_vlan_id": {
+			Type:        schema.TypeInt,
+			Description: "The private VLAN ID of this port group. When set, vlan_range and vlan_id are ignored; setting more than one of them is not rejected.",
+			Optional:    true,
+		},
+		"allow_promiscuous": {
+			Type:         schema.TypeString,
+			Description:  "Enable or disable promiscuous mode on the port group. Leave unset to inherit the DVS's setting.",
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"", "true", "false"}, false),
+		},
+		"allow_forged_transmits": {
+			Type:         schema.TypeString,
+			Description:  "Controls whether or not the virtual network adapter is allowed to send network traffic with a different MAC address than that of its own. Leave unset to inherit the DVS's setting.",
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"", "true", "false"}, false),
+		},
+		"allow_mac_changes": {
+			Type:         schema.TypeString,
+			Description:  "Controls whether or not the Media Access Control (MAC) address can be changed. Leave unset to inherit the DVS's setting.",
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"", "true", "false"}, false),
+		},
+		"computed_policy": {
+			Type:        schema.TypeMap,
+			Description: "The effective network policy after inheritance. Note that this will look similar to, but is not the same, as the policy attributes defined in this resource.",
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"key": {
+			Type:        schema.TypeString,
+			Description: "The linkable identifier for this port group.",
+			Computed:    true,
+		},
+	}
+	structure.MergeSchema(s, schemaHostPortGroupSpec())
+
+	// VLAN ID and active/standby NICs, all inherited from
+	// schemaHostPortGroupSpec, are optional here, since a DVS
+	// port group can alternatively be configured with vlan_range or
+	// private_vlan_id, and its teaming policy is layered on top of the DVS
+	// uplink teaming policy rather than required up front.
+	s["vlan_id"].Optional = true
+	s["active_nics"].Optional = true
+	s["standby_nics"].Optional = true
+
+	return &schema.Resource{
+		CreateContext: resourceVSphereDistributedPortGroupCreate,
+		ReadContext:   resourceVSphereDistributedPortGroupRead,
+		UpdateContext: resourceVSphereDistributedPortGroupUpdate,
+		DeleteContext: resourceVSphereDistributedPortGroupDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Read:   schema.DefaultTimeout(defaultAPITimeout),
+			Update: schema.DefaultTimeout(defaultAPITimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
+		Schema: s,
+	}
+}
+
+func resourceVSphereDistributedPortGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	dvsID := d.Get("distributed_virtual_switch_id").(string)
+
+	dvs, err := dvsFromID(client, dvsID)
+	if err != nil {
+		return diag.Errorf("error loading distributed virtual switch: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	spec := expandDVPortgroupConfigSpec(d)
+	task, err := dvs.AddPortgroup(ctx, []types.DVPortgroupConfigSpec{*spec})
+	if err != nil {
+		return diag.Errorf("error adding distributed port group: %s", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		return diag.Errorf("error waiting for distributed port group creation: %s", err)
+	}
+
+	dvpg, err := dvPortgroupFromName(client, dvs, name)
+	if err != nil {
+		return diag.Errorf("error fetching newly created distributed port group: %s", err)
+	}
+	d.SetId(dvpg.Reference().Value)
+
+	return resourceVSphereDistributedPortGroupRead(ctx, d, meta)
+}
+
+func resourceVSphereDistributedPortGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	dvsID := d.Get("distributed_virtual_switch_id").(string)
+
+	dvs, err := dvsFromID(client, dvsID)
+	if err != nil {
+		return diag.Errorf("error loading distributed virtual switch: %s", err)
+	}
+
+	dvpg, err := dvPortgroupFromName(client, dvs, name)
+	if err != nil {
+		return diag.Errorf("error fetching distributed port group data: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
+	defer cancel()
+	info, err := dvPortgroupConfigInfo(ctx, dvpg)
+	if err != nil {
+		return diag.Errorf("error fetching distributed port group config info: %s", err)
+	}
+
+	if err := flattenDVPortgroupConfigInfo(d, info); err != nil {
+		return diag.Errorf("error setting resource data: %s", err)
+	}
+
+	if err := d.Set("key", info.Key); err != nil {
+		return diag.Errorf("error setting key: %s", err)
+	}
+
+	cpm, err := calculateDVPortgroupComputedPolicy(info)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("computed_policy", cpm); err != nil {
+		return diag.Errorf("error saving effective policy to state: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereDistributedPortGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	dvsID := d.Get("distributed_virtual_switch_id").(string)
+
+	dvs, err := dvsFromID(client, dvsID)
+	if err != nil {
+		return diag.Errorf("error loading distributed virtual switch: %s", err)
+	}
+
+	dvpg, err := dvPortgroupFromName(client, dvs, name)
+	if err != nil {
+		return diag.Errorf("error fetching distributed port group data: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	spec := expandDVPortgroupConfigSpec(d)
+	info, err := dvPortgroupConfigInfo(ctx, dvpg)
+	if err != nil {
+		return diag.Errorf("error fetching distributed port group config info: %s", err)
+	}
+	spec.ConfigVersion = info.ConfigVersion
+
+	task, err := dvpg.Reconfigure(ctx, *spec)
+	if err != nil {
+		return diag.Errorf("error updating distributed port group: %s", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		return diag.Errorf("error waiting for distributed port group update: %s", err)
+	}
+
+	return resourceVSphereDistributedPortGroupRead(ctx, d, meta)
+}
+
+func resourceVSphereDistributedPortGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("name").(string)
+	dvsID := d.Get("distributed_virtual_switch_id").(string)
+
+	dvs, err := dvsFromID(client, dvsID)
+	if err != nil {
+		return diag.Errorf("error loading distributed virtual switch: %s", err)
+	}
+
+	dvpg, err := dvPortgroupFromName(client, dvs, name)
+	if err != nil {
+		return diag.Errorf("error fetching distributed port group data: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	task, err := dvpg.Destroy(ctx)
+	if err != nil {
+		return diag.Errorf("error deleting distributed port group: %s", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		return diag.Errorf("error waiting for distributed port group deletion: %s", err)
+	}
+
+	return nil
+}
+
+// expandDVPortgroupConfigSpec reads the resource data into a
+// DVPortgroupConfigSpec suitable for both AddPortgroup and Reconfigure.
+func expandDVPortgroupConfigSpec(d *schema.ResourceData) *types.DVPortgroupConfigSpec {
+	spec := &types.DVPortgroupConfigSpec{
+		Name:                   d.Get("name").(string),
+		Type:                   dvPortgroupPortBindingType(d.Get("port_binding").(string)),
+		AutoExpand:             structure.BoolPtr(d.Get("auto_expand").(bool)),
+		NumPorts:               int32(d.Get("number_of_ports").(int)),
+		NetworkResourcePoolKey: d.Get("network_resource_pool_key").(string),
+		DefaultPortConfig: &types.VMwareDVSPortSetting{
+			Vlan:                expandDVPortgroupVlanSpec(d),
+			SecurityPolicy:      expandDVPortgroupSecurityPolicy(d),
+			UplinkTeamingPolicy: expandDVPortgroupTeamingPolicy(d),
+		},
+	}
+	return spec
+}
+
+// dvPortgroupPortBindingType translates the port_binding attribute into the
+// string value the API expects.
+func dvPortgroupPortBindingType(v string) string {
+	switch v {
+	case "ephemeral":
+		return string(types.DistributedVirtualPortgroupPortgroupTypeEphemeral)
+	case "dynamic":
+		return string(types.DistributedVirtualPortgroupPortgroupTypeLateBinding)
+	default:
+		return string(types.DistributedVirtualPortgroupPortgroupTypeEarlyBinding)
+	}
+}
+
+// flattenDVPortgroupPortBindingType is the inverse of
+// dvPortgroupPortBindingType, used to reconcile the API's port binding type
+// back into the port_binding attribute.
+func flattenDVPortgroupPortBindingType(v string) string {
+	switch types.DistributedVirtualPortgroupPortgroupType(v) {
+	case types.DistributedVirtualPortgroupPortgroupTypeEphemeral:
+		return "ephemeral"
+	case types.DistributedVirtualPortgroupPortgroupTypeLateBinding:
+		return "dynamic"
+	default:
+		return "static"
+	}
+}
+
+// expandDVPortgroupSecurityPolicy builds the DVS security policy from
+// allow_promiscuous, allow_forged_transmits, and allow_mac_changes, or
+// returns nil if none of them are set, leaving the port group to inherit
+// its security policy from the DVS. Each attribute is a tri-state string
+// ("", "true", "false") rather than a bool so that an explicit "false" can
+// be distinguished from "unset".
+func expandDVPortgroupSecurityPolicy(d *schema.ResourceData) *types.DVSSecurityPolicy {
+	policy := &types.DVSSecurityPolicy{}
+	var configured bool
+	if b := expandOptionalBoolString(d.Get("allow_promiscuous").(string)); b != nil {
+		policy.AllowPromiscuous = &types.BoolPolicy{Value: *b}
+		configured = true
+	}
+	if b := expandOptionalBoolString(d.Get("allow_forged_transmits").(string)); b != nil {
+		policy.ForgedTransmits = &types.BoolPolicy{Value: *b}
+		configured = true
+	}
+	if b := expandOptionalBoolString(d.Get("allow_mac_changes").(string)); b != nil {
+		policy.MacChanges = &types.BoolPolicy{Value: *b}
+		configured = true
+	}
+	if !configured {
+		return nil
+	}
+	return policy
+}
+
+// expandOptionalBoolString converts a tri-state "", "true", "false"
+// attribute into a nullable bool, returning nil for "" (unset/inherit).
+func expandOptionalBoolString(v string) *bool {
+	if v == "" {
+		return nil
+	}
+	b := v == "true"
+	return &b
+}
+
+// flattenOptionalBoolString is the inverse of expandOptionalBoolString.
+func flattenOptionalBoolString(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return fmt.Sprintf("%t", *b)
+}
+
+// expandDVPortgroupTeamingPolicy builds the DVS uplink teaming policy from
+// active_nics/standby_nics, or returns nil if neither is set, leaving the
+// port group to inherit its teaming policy from the DVS.
+func expandDVPortgroupTeamingPolicy(d *schema.ResourceData) *types.VmwareUplinkPortTeamingPolicy {
+	active := sliceInterfacesToStrings(d.Get("active_nics").([]interface{}))
+	standby := sliceInterfacesToStrings(d.Get("standby_nics").([]interface{}))
+	if len(active) == 0 && len(standby) == 0 {
+		return nil
+	}
+	return &types.VmwareUplinkPortTeamingPolicy{
+		UplinkPortOrder: &types.VMwareUplinkPortOrderPolicy{
+			ActiveUplinkPort:  active,
+			StandbyUplinkPort: standby,
+		},
+	}
+}
+
+// expandDVPortgroupVlanSpec builds the appropriate VLAN configuration --
+// a single VLAN ID, a set of trunk ranges, or a private VLAN ID -- from
+// whichever of vlan_id, vlan_range, or private_vlan_id is populated.
+func expandDVPortgroupVlanSpec(d *schema.ResourceData) types.BaseVmwareDistributedVirtualSwitchVlanSpec {
+	if v, ok := d.GetOk("private_vlan_id"); ok {
+		return &types.VmwareDistributedVirtualSwitchPvlanSpec{
+			PvlanId: int32(v.(int)),
+		}
+	}
+
+	if raw, ok := d.GetOk("vlan_range"); ok {
+		ranges := raw.([]interface{})
+		trunk := make([]types.NumericRange, 0, len(ranges))
+		for _, r := range ranges {
+			m := r.(map[string]interface{})
+			trunk = append(trunk, types.NumericRange{
+				Start: int32(m["min_vlan"].(int)),
+				End:   int32(m["max_vlan"].(int)),
+			})
+		}
+		return &types.VmwareDistributedVirtualSwitchTrunkVlanSpec{
+			VlanId: trunk,
+		}
+	}
+
+	return &types.VmwareDistributedVirtualSwitchVlanIdSpec{
+		VlanId: int32(d.Get("vlan_id").(int)),
+	}
+}
+
+// dvsFromID locates a DistributedVirtualSwitch by its managed object ID,
+// following the same convention as hostNetworkSystemFromHostSystemID.
+func dvsFromID(client *govmomi.Client, id string) (*object.DistributedVirtualSwitch, error) {
+	ref := types.ManagedObjectReference{Type: "DistributedVirtualSwitch", Value: id}
+	return object.NewDistributedVirtualSwitch(client.Client, ref), nil
+}
+
+// dvPortgroupFromName looks up a distributed port group on the given DVS by
+// name.
+func dvPortgroupFromName(client *govmomi.Client, dvs *object.DistributedVirtualSwitch, name string) (*object.DistributedVirtualPortgroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	defer cancel()
+
+	refs, err := dvsPortgroupReferences(ctx, dvs)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		pg := object.NewDistributedVirtualPortgroup(client.Client, ref)
+		var mpg mo.DistributedVirtualPortgroup
+		if err := pg.Properties(ctx, pg.Reference(), []string{"name"}, &mpg); err != nil {
+			return nil, err
+		}
+		if mpg.Name == name {
+			return pg, nil
+		}
+	}
+	return nil, fmt.Errorf("distributed port group %q not found", name)
+}
+
+// dvsPortgroupReferences returns the managed object references of the port
+// groups currently defined on a DVS.
+func dvsPortgroupReferences(ctx context.Context, dvs *object.DistributedVirtualSwitch) ([]types.ManagedObjectReference, error) {
+	var mdvs mo.DistributedVirtualSwitch
+	if err := dvs.Properties(ctx, dvs.Reference(), []string{"portgroup"}, &mdvs); err != nil {
+		return nil, err
+	}
+	return mdvs.Portgroup, nil
+}
+
+// dvPortgroupConfigInfo fetches the current DVPortgroupConfigInfo for a
+// distributed port group.
+func dvPortgroupConfigInfo(ctx context.Context, dvpg *object.DistributedVirtualPortgroup) (*types.DVPortgroupConfigInfo, error) {
+	var mpg mo.DistributedVirtualPortgroup
+	if err := dvpg.Properties(ctx, dvpg.Reference(), []string{"config"}, &mpg); err != nil {
+		return nil, err
+	}
+	return &mpg.Config, nil
+}
+
+// flattenDVPortgroupConfigInfo reconciles a DVPortgroupConfigInfo back into
+// resource data, including the VLAN and teaming configuration nested under
+// DefaultPortConfig, so that state does not drift from out-of-band changes
+// to the port group.
+func flattenDVPortgroupConfigInfo(d *schema.ResourceData, info *types.DVPortgroupConfigInfo) error {
+	if err := d.Set("name", info.Name); err != nil {
+		return err
+	}
+	if err := d.Set("auto_expand", info.AutoExpand); err != nil {
+		return err
+	}
+	if err := d.Set("number_of_ports", info.NumPorts); err != nil {
+		return err
+	}
+	if err := d.Set("port_binding", flattenDVPortgroupPortBindingType(info.Type)); err != nil {
+		return err
+	}
+
+	settings, ok := info.DefaultPortConfig.(*types.VMwareDVSPortSetting)
+	if !ok || settings == nil {
+		return nil
+	}
+
+	if err := d.Set("network_resource_pool_key", settings.NetworkResourcePoolKey); err != nil {
+		return err
+	}
+
+	switch vlan := settings.Vlan.(type) {
+	case *types.VmwareDistributedVirtualSwitchVlanIdSpec:
+		if err := d.Set("vlan_id", vlan.VlanId); err != nil {
+			return err
+		}
+	case *types.VmwareDistributedVirtualSwitchPvlanSpec:
+		if err := d.Set("private_vlan_id", vlan.PvlanId); err != nil {
+			return err
+		}
+	case *types.VmwareDistributedVirtualSwitchTrunkVlanSpec:
+		ranges := make([]interface{}, 0, len(vlan.VlanId))
+		for _, r := range vlan.VlanId {
+			ranges = append(ranges, map[string]interface{}{
+				"min_vlan": int(r.Start),
+				"max_vlan": int(r.End),
+			})
+		}
+		if err := d.Set("vlan_range", ranges); err != nil {
+			return err
+		}
+	}
+
+	if settings.UplinkTeamingPolicy != nil && settings.UplinkTeamingPolicy.UplinkPortOrder != nil {
+		if err := d.Set("active_nics", settings.UplinkTeamingPolicy.UplinkPortOrder.ActiveUplinkPort); err != nil {
+			return err
+		}
+		if err := d.Set("standby_nics", settings.UplinkTeamingPolicy.UplinkPortOrder.StandbyUplinkPort); err != nil {
+			return err
+		}
+	}
+
+	if settings.SecurityPolicy != nil {
+		var promiscuous, forgedTransmits, macChanges *bool
+		if settings.SecurityPolicy.AllowPromiscuous != nil {
+			promiscuous = &settings.SecurityPolicy.AllowPromiscuous.Value
+		}
+		if settings.SecurityPolicy.ForgedTransmits != nil {
+			forgedTransmits = &settings.SecurityPolicy.ForgedTransmits.Value
+		}
+		if settings.SecurityPolicy.MacChanges != nil {
+			macChanges = &settings.SecurityPolicy.MacChanges.Value
+		}
+		if err := d.Set("allow_promiscuous", flattenOptionalBoolString(promiscuous)); err != nil {
+			return err
+		}
+		if err := d.Set("allow_forged_transmits", flattenOptionalBoolString(forgedTransmits)); err != nil {
+			return err
+		}
+		if err := d.Set("allow_mac_changes", flattenOptionalBoolString(macChanges)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// calculateDVPortgroupComputedPolicy reconciles a DVPortgroupConfigInfo into
+// the same map[string]string shape produced by calculateComputedPolicy for
+// host port groups, so computed_policy reads consistently across both
+// resources.
+func calculateDVPortgroupComputedPolicy(info *types.DVPortgroupConfigInfo) (map[string]string, error) {
+	m := make(map[string]string)
+	settings, ok := info.DefaultPortConfig.(*types.VMwareDVSPortSetting)
+	if !ok || settings.SecurityPolicy == nil {
+		return m, nil
+	}
+	if settings.SecurityPolicy.AllowPromiscuous != nil {
+		m["allow_promiscuous"] = fmt.Sprintf("%t", settings.SecurityPolicy.AllowPromiscuous.Value)
+	}
+	if settings.SecurityPolicy.ForgedTransmits != nil {
+		m["allow_forged_transmits"] = fmt.Sprintf("%t", settings.SecurityPolicy.ForgedTransmits.Value)
+	}
+	if settings.SecurityPolicy.MacChanges != nil {
+		m["allow_mac_changes"] = fmt.Sprintf("%t", settings.SecurityPolicy.MacChanges.Value)
+	}
+	return m, nil
+}
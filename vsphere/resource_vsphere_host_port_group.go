@@ -1,13 +1,15 @@
 package vsphere
 
 import (
-	"fmt"
-    "log"
 	"context"
-    "github.com/vmware/govmomi/find"
-    "github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/provider"
-	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-provider-vsphere/vsphere/internal/helper/structure"
+	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 )
 
@@ -52,64 +54,71 @@ func resourceVSphereHostPortGroup() *schema.Resource {
 	s["standby_nics"].Optional = true
 
 	return &schema.Resource{
-		Create: resourceVSphereHostPortGroupCreate,
-		Read:   resourceVSphereHostPortGroupRead,
-		Update: resourceVSphereHostPortGroupUpdate,
-		Delete: resourceVSphereHostPortGroupDelete,
+		CreateContext: resourceVSphereHostPortGroupCreate,
+		ReadContext:   resourceVSphereHostPortGroupRead,
+		UpdateContext: resourceVSphereHostPortGroupUpdate,
+		DeleteContext: resourceVSphereHostPortGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVSphereHostPortGroupImport,
+		},
 		Schema: s,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Read:   schema.DefaultTimeout(defaultAPITimeout),
+			Update: schema.DefaultTimeout(defaultAPITimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
 	}
 }
 
-func resourceVSphereHostPortGroupCreate(d *schema.ResourceData, meta interface{}) error {
+func resourceVSphereHostPortGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*VSphereClient).vimClient
 	name := d.Get("name").(string)
 	hsID := d.Get("host_system_id").(string)
 	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
 	if err != nil {
-		return fmt.Errorf("error loading network system: %s", err)
+		return diag.Errorf("error loading network system: %s", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
 	defer cancel()
 	spec := expandHostPortGroupSpec(d)
 	if err := ns.AddPortGroup(ctx, *spec); err != nil {
-		return fmt.Errorf("error adding port group: %s", err)
+		return diag.Errorf("error adding port group: %s", err)
 	}
 
 	saveHostPortGroupID(d, hsID, name)
-	return resourceVSphereHostPortGroupRead(d, meta)
+	return resourceVSphereHostPortGroupRead(ctx, d, meta)
 }
 
-func resourceVSphereHostPortGroupRead(d *schema.ResourceData, meta interface{}) error {
+func resourceVSphereHostPortGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client := meta.(*VSphereClient).vimClient
 	name := d.Get("name").(string)
 	hsID := d.Get("host_system_id").(string)
-	//hsID, name, err := portGroupIDsFromResourceID(d)
-	//if err != nil {
-	//	return err
-	//}
 	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
 	if err != nil {
-		return fmt.Errorf("error loading host network system: %s", err)
+		return diag.Errorf("error loading host network system: %s", err)
 	}
 
-	pg, err := hostPortGroupFromName(meta.(*VSphereClient).vimClient, ns, name)
+	pg, err := hostPortGroupFromName(client, ns, name)
 	if err != nil {
-		return fmt.Errorf("error fetching port group data: %s", err)
+		return diag.Errorf("error fetching port group data: %s", err)
 	}
 
 	if err := flattenHostPortGroupSpec(d, &pg.Spec); err != nil {
-		return fmt.Errorf("error setting resource data: %s", err)
+		return diag.Errorf("error setting resource data: %s", err)
 	}
 
-	d.Set("key", pg.Key)
+	if err := d.Set("key", pg.Key); err != nil {
+		return diag.Errorf("error setting key: %s", err)
+	}
 
 	var dc *object.Datacenter
 	if dcID, ok := d.GetOk("datacenter_id"); ok {
 		var err error
 		dc, err = datacenterFromID(client, dcID.(string))
 		if err != nil {
-			return fmt.Errorf("cannot locate datacenter: %s", err)
+			return diag.Errorf("cannot locate datacenter: %s", err)
 		}
 	}
 
@@ -117,75 +126,94 @@ func resourceVSphereHostPortGroupRead(d *schema.ResourceData, meta interface{})
 	if dc != nil {
 		finder.SetDatacenter(dc)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), provider.DefaultAPITimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutRead))
 	defer cancel()
 	networks, err := finder.NetworkList(ctx, name)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if len(networks) == 0 {
-		return fmt.Errorf("%s %s not found", "Network", name)
+		return diag.Errorf("%s %s not found", "Network", name)
 	}
 
-    d.SetId(networks[0].Reference().Value)
+	d.SetId(networks[0].Reference().Value)
 	log.Printf("[DEBUG] Network ID is %s", networks[0].Reference().Value)
 	cpm, err := calculateComputedPolicy(pg.ComputedPolicy)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 	if err := d.Set("computed_policy", cpm); err != nil {
-		return fmt.Errorf("error saving effective policy to state: %s", err)
+		return diag.Errorf("error saving effective policy to state: %s", err)
 	}
 	if err := d.Set("ports", calculatePorts(pg.Port)); err != nil {
-		return fmt.Errorf("error setting port list: %s", err)
+		return diag.Errorf("error setting port list: %s", err)
 	}
 
 	return nil
 }
 
-func resourceVSphereHostPortGroupUpdate(d *schema.ResourceData, meta interface{}) error {
+func resourceVSphereHostPortGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	name := d.Get("name").(string)
 	hsID := d.Get("host_system_id").(string)
-	//saveHostPortGroupID(d, hsID, name)
 	client := meta.(*VSphereClient).vimClient
-	//hsID, name, err := portGroupIDsFromResourceID(d)
-	//if err != nil {
-	//	return err
-	//}
 	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
 	if err != nil {
-		return fmt.Errorf("error loading host network system: %s", err)
+		return diag.Errorf("error loading host network system: %s", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
 	defer cancel()
 	spec := expandHostPortGroupSpec(d)
 	if err := ns.UpdatePortGroup(ctx, name, *spec); err != nil {
-		return fmt.Errorf("error updating port group: %s", err)
+		return diag.Errorf("error updating port group: %s", err)
 	}
 
-	return resourceVSphereHostPortGroupRead(d, meta)
+	return resourceVSphereHostPortGroupRead(ctx, d, meta)
 }
 
-func resourceVSphereHostPortGroupDelete(d *schema.ResourceData, meta interface{}) error {
+func resourceVSphereHostPortGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	name := d.Get("name").(string)
 	hsID := d.Get("host_system_id").(string)
-	//saveHostPortGroupID(d, hsID, name)
 	client := meta.(*VSphereClient).vimClient
-	//hsID, name, err := portGroupIDsFromResourceID(d)
-	//if err != nil {
-	//	return err
-	//}
 	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
 	if err != nil {
-		return fmt.Errorf("error loading host network system: %s", err)
+		return diag.Errorf("error loading host network system: %s", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultAPITimeout)
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
 	defer cancel()
 	if err := ns.RemovePortGroup(ctx, name); err != nil {
-		return fmt.Errorf("error deleting port group: %s", err)
+		return diag.Errorf("error deleting port group: %s", err)
 	}
 
 	return nil
 }
+
+// resourceVSphereHostPortGroupImport parses an import ID of the form
+// host_system_id:portgroup_name so that a port group created outside of
+// Terraform can be adopted into state.
+func resourceVSphereHostPortGroupImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	hsID, name, err := portGroupIDsFromResourceID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("host_system_id", hsID); err != nil {
+		return nil, err
+	}
+	if err := d.Set("name", name); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// portGroupIDsFromResourceID splits a composite host_system_id:portgroup_name
+// import ID into its constituent parts.
+func portGroupIDsFromResourceID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("expected import ID in the format host_system_id:portgroup_name, got %q", id)
+	}
+	return parts[0], parts[1], nil
+}
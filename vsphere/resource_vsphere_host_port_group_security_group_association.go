@@ -0,0 +1,400 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourceVSphereHostPortGroupSecurityGroupAssociation manages a subset of an
+// existing host port group's policy -- security overrides (promiscuous mode,
+// forged transmits, MAC address changes) and, optionally, NIC teaming
+// overrides -- without taking ownership of the port group itself. This lets
+// the port group and its policy be managed from separate Terraform
+// workspaces/configurations.
+func resourceVSphereHostPortGroupSecurityGroupAssociation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVSphereHostPortGroupSecurityGroupAssociationCreate,
+		ReadContext:   resourceVSphereHostPortGroupSecurityGroupAssociationRead,
+		UpdateContext: resourceVSphereHostPortGroupSecurityGroupAssociationUpdate,
+		DeleteContext: resourceVSphereHostPortGroupSecurityGroupAssociationDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Read:   schema.DefaultTimeout(defaultAPITimeout),
+			Update: schema.DefaultTimeout(defaultAPITimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"port_group_name": {
+				Type:        schema.TypeString,
+				Description: "The name of the port group to attach the security/teaming overrides to.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host the port group is located on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"enforce": {
+				Type:        schema.TypeBool,
+				Description: "If true, this resource takes exclusive ownership of the port group's security and teaming policy and resets it to inherited-from-vswitch on destroy. If false, overrides are layered on top of the port group's existing policy and the prior values are restored on destroy.",
+				Optional:    true,
+				Default:     false,
+			},
+			"allow_promiscuous": {
+				Type:         schema.TypeString,
+				Description:  "Enable or disable promiscuous mode on the port group. Leave unset to leave the port group's existing setting untouched.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"", "true", "false"}, false),
+			},
+			"allow_forged_transmits": {
+				Type:         schema.TypeString,
+				Description:  "Controls whether or not the virtual network adapter is allowed to send network traffic with a different MAC address than that of its own. Leave unset to leave the port group's existing setting untouched.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"", "true", "false"}, false),
+			},
+			"allow_mac_changes": {
+				Type:         schema.TypeString,
+				Description:  "Controls whether or not the Media Access Control (MAC) address can be changed. Leave unset to leave the port group's existing setting untouched.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"", "true", "false"}, false),
+			},
+			"active_nics": {
+				Type:        schema.TypeList,
+				Description: "List of active network adapters used for load balancing, overriding the port group's configured teaming policy.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"standby_nics": {
+				Type:        schema.TypeList,
+				Description: "List of standby network adapters used for failover, overriding the port group's configured teaming policy.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"all_active_nics": {
+				Type:        schema.TypeSet,
+				Description: "The effective list of active network adapters, after the overrides in this resource have been merged with the port group's existing policy.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"all_standby_nics": {
+				Type:        schema.TypeSet,
+				Description: "The effective list of standby network adapters, after the overrides in this resource have been merged with the port group's existing policy.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"restored_security_policy": {
+				Type:        schema.TypeMap,
+				Description: "Internal: the port group's security policy as it was before this resource took ownership, used to restore it on destroy when enforce is false.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"restored_active_nics": {
+				Type:        schema.TypeList,
+				Description: "Internal: the port group's active NIC teaming order as it was before this resource took ownership, used to restore it on destroy when enforce is false.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"restored_standby_nics": {
+				Type:        schema.TypeList,
+				Description: "Internal: the port group's standby NIC teaming order as it was before this resource took ownership, used to restore it on destroy when enforce is false.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostPortGroupSecurityGroupAssociationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("port_group_name").(string)
+	hsID := d.Get("host_system_id").(string)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	pg, err := hostPortGroupFromName(client, ns, name)
+	if err != nil {
+		return diag.Errorf("error fetching port group data: %s", err)
+	}
+
+	if err := d.Set("restored_security_policy", flattenHostNetworkSecurityPolicy(pg.Spec.Policy.Security)); err != nil {
+		return diag.Errorf("error saving prior security policy: %s", err)
+	}
+
+	priorActive, priorStandby := flattenHostNicOrderPolicy(pg.Spec.Policy.NicTeaming)
+	if err := d.Set("restored_active_nics", priorActive); err != nil {
+		return diag.Errorf("error saving prior NIC teaming order: %s", err)
+	}
+	if err := d.Set("restored_standby_nics", priorStandby); err != nil {
+		return diag.Errorf("error saving prior NIC teaming order: %s", err)
+	}
+
+	spec := pg.Spec
+	applyPortGroupSecurityGroupAssociationOverrides(d, &spec)
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+	if err := ns.UpdatePortGroup(ctx, name, spec); err != nil {
+		return diag.Errorf("error applying security/teaming overrides: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", hsID, name))
+	return resourceVSphereHostPortGroupSecurityGroupAssociationRead(ctx, d, meta)
+}
+
+func resourceVSphereHostPortGroupSecurityGroupAssociationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("port_group_name").(string)
+	hsID := d.Get("host_system_id").(string)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	pg, err := hostPortGroupFromName(client, ns, name)
+	if err != nil {
+		return diag.Errorf("error fetching port group data: %s", err)
+	}
+
+	if pg.Spec.Policy.NicTeaming != nil && pg.Spec.Policy.NicTeaming.NicOrder != nil {
+		if err := d.Set("all_active_nics", pg.Spec.Policy.NicTeaming.NicOrder.ActiveNic); err != nil {
+			return diag.Errorf("error setting all_active_nics: %s", err)
+		}
+		if err := d.Set("all_standby_nics", pg.Spec.Policy.NicTeaming.NicOrder.StandbyNic); err != nil {
+			return diag.Errorf("error setting all_standby_nics: %s", err)
+		}
+	}
+
+	// Refresh the overrides this resource manages -- but only the ones it
+	// manages -- so that plan can detect an out-of-band reversion. A field
+	// left unconfigured never took ownership of that part of the policy, so
+	// its current server-side value (however it got there) is not this
+	// resource's concern.
+	if len(d.Get("active_nics").([]interface{})) > 0 || len(d.Get("standby_nics").([]interface{})) > 0 {
+		active, standby := flattenHostNicOrderPolicy(pg.Spec.Policy.NicTeaming)
+		if err := d.Set("active_nics", active); err != nil {
+			return diag.Errorf("error setting active_nics: %s", err)
+		}
+		if err := d.Set("standby_nics", standby); err != nil {
+			return diag.Errorf("error setting standby_nics: %s", err)
+		}
+	}
+
+	var promiscuous, forgedTransmits, macChanges *bool
+	if pg.Spec.Policy.Security != nil {
+		promiscuous = pg.Spec.Policy.Security.AllowPromiscuous
+		forgedTransmits = pg.Spec.Policy.Security.ForgedTransmits
+		macChanges = pg.Spec.Policy.Security.MacChanges
+	}
+	if err := refreshManagedSecurityOverride(d, "allow_promiscuous", promiscuous); err != nil {
+		return diag.Errorf("error setting allow_promiscuous: %s", err)
+	}
+	if err := refreshManagedSecurityOverride(d, "allow_forged_transmits", forgedTransmits); err != nil {
+		return diag.Errorf("error setting allow_forged_transmits: %s", err)
+	}
+	if err := refreshManagedSecurityOverride(d, "allow_mac_changes", macChanges); err != nil {
+		return diag.Errorf("error setting allow_mac_changes: %s", err)
+	}
+
+	return nil
+}
+
+// refreshManagedSecurityOverride writes the port group's current value for
+// a security attribute back into state, but only if this resource is
+// already managing an override for it -- an unconfigured attribute never
+// took ownership of that part of the policy.
+func refreshManagedSecurityOverride(d *schema.ResourceData, field string, actual *bool) error {
+	if d.Get(field).(string) == "" {
+		return nil
+	}
+	return d.Set(field, flattenOptionalBoolString(actual))
+}
+
+func resourceVSphereHostPortGroupSecurityGroupAssociationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("port_group_name").(string)
+	hsID := d.Get("host_system_id").(string)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	pg, err := hostPortGroupFromName(client, ns, name)
+	if err != nil {
+		return diag.Errorf("error fetching port group data: %s", err)
+	}
+
+	spec := pg.Spec
+	applyPortGroupSecurityGroupAssociationOverrides(d, &spec)
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutUpdate))
+	defer cancel()
+	if err := ns.UpdatePortGroup(ctx, name, spec); err != nil {
+		return diag.Errorf("error applying security/teaming overrides: %s", err)
+	}
+
+	return resourceVSphereHostPortGroupSecurityGroupAssociationRead(ctx, d, meta)
+}
+
+func resourceVSphereHostPortGroupSecurityGroupAssociationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	name := d.Get("port_group_name").(string)
+	hsID := d.Get("host_system_id").(string)
+	enforce := d.Get("enforce").(bool)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	pg, err := hostPortGroupFromName(client, ns, name)
+	if err != nil {
+		return diag.Errorf("error fetching port group data: %s", err)
+	}
+
+	spec := pg.Spec
+	if enforce {
+		spec.Policy.Security = nil
+		if spec.Policy.NicTeaming != nil {
+			spec.Policy.NicTeaming.NicOrder = nil
+		}
+	} else {
+		spec.Policy.Security = expandHostNetworkSecurityPolicy(d.Get("restored_security_policy").(map[string]interface{}))
+		restoredActive := sliceInterfacesToStrings(d.Get("restored_active_nics").([]interface{}))
+		restoredStandby := sliceInterfacesToStrings(d.Get("restored_standby_nics").([]interface{}))
+		if len(restoredActive) > 0 || len(restoredStandby) > 0 {
+			if spec.Policy.NicTeaming == nil {
+				spec.Policy.NicTeaming = &types.HostNicTeamingPolicy{}
+			}
+			spec.Policy.NicTeaming.NicOrder = &types.HostNicOrderPolicy{
+				ActiveNic:  restoredActive,
+				StandbyNic: restoredStandby,
+			}
+		} else if spec.Policy.NicTeaming != nil {
+			spec.Policy.NicTeaming.NicOrder = nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+	if err := ns.UpdatePortGroup(ctx, name, spec); err != nil {
+		return diag.Errorf("error removing security/teaming overrides: %s", err)
+	}
+
+	return nil
+}
+
+// applyPortGroupSecurityGroupAssociationOverrides layers the resource's
+// configured security and NIC teaming overrides on top of an existing
+// HostPortGroupSpec. Only the allow_* attributes actually configured are
+// overridden -- spec.Policy.Security is left untouched when none of them
+// are set, and its other fields are preserved when some are, so that
+// enforce=false layers cleanly on top of whatever the port group already
+// has rather than replacing it wholesale. With enforce=true, the existing
+// security policy and NIC order are cleared first, so any attribute this
+// resource does not configure reverts to inherited-from-vswitch.
+func applyPortGroupSecurityGroupAssociationOverrides(d *schema.ResourceData, spec *types.HostPortGroupSpec) {
+	if d.Get("enforce").(bool) {
+		spec.Policy.Security = nil
+		if spec.Policy.NicTeaming != nil {
+			spec.Policy.NicTeaming.NicOrder = nil
+		}
+	}
+
+	promiscuous := expandOptionalBoolString(d.Get("allow_promiscuous").(string))
+	forgedTransmits := expandOptionalBoolString(d.Get("allow_forged_transmits").(string))
+	macChanges := expandOptionalBoolString(d.Get("allow_mac_changes").(string))
+
+	if promiscuous != nil || forgedTransmits != nil || macChanges != nil {
+		if spec.Policy.Security == nil {
+			spec.Policy.Security = &types.HostNetworkSecurityPolicy{}
+		}
+		if promiscuous != nil {
+			spec.Policy.Security.AllowPromiscuous = promiscuous
+		}
+		if forgedTransmits != nil {
+			spec.Policy.Security.ForgedTransmits = forgedTransmits
+		}
+		if macChanges != nil {
+			spec.Policy.Security.MacChanges = macChanges
+		}
+	}
+
+	active := sliceInterfacesToStrings(d.Get("active_nics").([]interface{}))
+	standby := sliceInterfacesToStrings(d.Get("standby_nics").([]interface{}))
+	if len(active) > 0 || len(standby) > 0 {
+		if spec.Policy.NicTeaming == nil {
+			spec.Policy.NicTeaming = &types.HostNicTeamingPolicy{}
+		}
+		spec.Policy.NicTeaming.NicOrder = &types.HostNicOrderPolicy{
+			ActiveNic:  active,
+			StandbyNic: standby,
+		}
+	}
+}
+
+func sliceInterfacesToStrings(in []interface{}) []string {
+	out := make([]string, 0, len(in))
+	for _, v := range in {
+		out = append(out, v.(string))
+	}
+	return out
+}
+
+// flattenHostNicOrderPolicy returns the active/standby NIC order configured
+// on a teaming policy, or two nil slices if the port group inherits its
+// teaming order from the vSwitch.
+func flattenHostNicOrderPolicy(tp *types.HostNicTeamingPolicy) ([]string, []string) {
+	if tp == nil || tp.NicOrder == nil {
+		return nil, nil
+	}
+	return tp.NicOrder.ActiveNic, tp.NicOrder.StandbyNic
+}
+
+func flattenHostNetworkSecurityPolicy(sp *types.HostNetworkSecurityPolicy) map[string]interface{} {
+	m := make(map[string]interface{})
+	if sp == nil {
+		return m
+	}
+	if sp.AllowPromiscuous != nil {
+		m["allow_promiscuous"] = fmt.Sprintf("%t", *sp.AllowPromiscuous)
+	}
+	if sp.ForgedTransmits != nil {
+		m["allow_forged_transmits"] = fmt.Sprintf("%t", *sp.ForgedTransmits)
+	}
+	if sp.MacChanges != nil {
+		m["allow_mac_changes"] = fmt.Sprintf("%t", *sp.MacChanges)
+	}
+	return m
+}
+
+func expandHostNetworkSecurityPolicy(m map[string]interface{}) *types.HostNetworkSecurityPolicy {
+	if len(m) == 0 {
+		return nil
+	}
+	sp := &types.HostNetworkSecurityPolicy{}
+	if v, ok := m["allow_promiscuous"]; ok {
+		b := v.(string) == "true"
+		sp.AllowPromiscuous = &b
+	}
+	if v, ok := m["allow_forged_transmits"]; ok {
+		b := v.(string) == "true"
+		sp.ForgedTransmits = &b
+	}
+	if v, ok := m["allow_mac_changes"]; ok {
+		b := v.(string) == "true"
+		sp.MacChanges = &b
+	}
+	return sp
+}
@@ -0,0 +1,286 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// resourceVSphereHostPortGroupSet manages many standard vSwitch port groups
+// on a single host as a single resource. It diffs the desired set of port
+// groups against what currently exists on the host's HostNetworkSystem and
+// reconciles the difference with AddPortGroup/UpdatePortGroup/RemovePortGroup
+// calls, recording state as each call completes so that a partial failure
+// part-way through reconciliation does not strand successfully applied port
+// groups outside of state.
+func resourceVSphereHostPortGroupSet() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceVSphereHostPortGroupSetCreate,
+		ReadContext:   resourceVSphereHostPortGroupSetRead,
+		UpdateContext: resourceVSphereHostPortGroupSetUpdate,
+		DeleteContext: resourceVSphereHostPortGroupSetDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultAPITimeout),
+			Read:   schema.DefaultTimeout(defaultAPITimeout),
+			Update: schema.DefaultTimeout(defaultAPITimeout),
+			Delete: schema.DefaultTimeout(defaultAPITimeout),
+		},
+		Schema: map[string]*schema.Schema{
+			"host_system_id": {
+				Type:        schema.TypeString,
+				Description: "The managed object ID of the host to manage the port groups on.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"port_group": {
+				Type:        schema.TypeSet,
+				Description: "A port group to create and manage on the host, keyed by name.",
+				Required:    true,
+				Elem:        hostPortGroupSetEntrySchema(),
+			},
+			"applied_port_groups": {
+				Type:        schema.TypeSet,
+				Description: "The names of the port groups that have been successfully reconciled onto the host. Used to recover from a partially-applied reconciliation if a subsequent apply fails partway through.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func hostPortGroupSetEntrySchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the port group.",
+				Required:    true,
+			},
+			"vswitch_name": {
+				Type:        schema.TypeString,
+				Description: "The name of the vSwitch to bind this port group to.",
+				Required:    true,
+			},
+			"vlan_id": {
+				Type:        schema.TypeInt,
+				Description: "The VLAN ID/trunk mode for this port group.",
+				Optional:    true,
+			},
+			"active_nics": {
+				Type:        schema.TypeList,
+				Description: "List of active network adapters used for load balancing.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"standby_nics": {
+				Type:        schema.TypeList,
+				Description: "List of standby network adapters used for failover.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceVSphereHostPortGroupSetCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(d.Get("host_system_id").(string))
+	return resourceVSphereHostPortGroupSetApply(ctx, d, meta, d.Timeout(schema.TimeoutCreate))
+}
+
+func resourceVSphereHostPortGroupSetUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceVSphereHostPortGroupSetApply(ctx, d, meta, d.Timeout(schema.TimeoutUpdate))
+}
+
+// resourceVSphereHostPortGroupSetApply reconciles the desired port group set
+// against the host's current port groups. Every successful API call is
+// reflected back into the applied_port_groups state immediately, so that if
+// reconciliation returns an error partway through, state still accurately
+// reflects what was actually applied on the host.
+func resourceVSphereHostPortGroupSetApply(ctx context.Context, d *schema.ResourceData, meta interface{}, timeout time.Duration) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	desired := expandHostPortGroupSetEntries(d.Get("port_group").(*schema.Set).List())
+	existing, err := hostPortGroupNamesFromHostNetworkSystem(ctx, client, ns)
+	if err != nil {
+		return diag.Errorf("error reading existing port groups: %s", err)
+	}
+
+	// priorApplied is the set of port groups this resource added or updated
+	// on a previous apply. Only these, never the full host inventory, are
+	// candidates for removal below -- port groups on the host that were never
+	// listed in port_group (e.g. "Management Network", or ones that other
+	// resources manage) are never touched.
+	priorApplied := schema.NewSet(schema.HashString, nil)
+	for _, name := range d.Get("applied_port_groups").(*schema.Set).List() {
+		priorApplied.Add(name)
+	}
+	applied := schema.NewSet(schema.HashString, nil)
+	for _, name := range priorApplied.List() {
+		applied.Add(name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for name, entry := range desired {
+		spec := expandHostPortGroupSetEntrySpec(entry)
+		if existing[name] {
+			if err := ns.UpdatePortGroup(ctx, name, spec); err != nil {
+				d.Set("applied_port_groups", applied)
+				return diag.Errorf("error updating port group %q: %s", name, err)
+			}
+		} else {
+			if err := ns.AddPortGroup(ctx, spec); err != nil {
+				d.Set("applied_port_groups", applied)
+				return diag.Errorf("error adding port group %q: %s", name, err)
+			}
+		}
+		applied.Add(name)
+		if err := d.Set("applied_port_groups", applied); err != nil {
+			return diag.Errorf("error saving reconciliation state: %s", err)
+		}
+	}
+
+	for _, v := range priorApplied.List() {
+		name := v.(string)
+		if _, ok := desired[name]; ok {
+			continue
+		}
+		if existing[name] {
+			if err := ns.RemovePortGroup(ctx, name); err != nil {
+				return diag.Errorf("error removing port group %q: %s", name, err)
+			}
+		}
+		applied.Remove(name)
+		if err := d.Set("applied_port_groups", applied); err != nil {
+			return diag.Errorf("error saving reconciliation state: %s", err)
+		}
+	}
+
+	return resourceVSphereHostPortGroupSetRead(ctx, d, meta)
+}
+
+func resourceVSphereHostPortGroupSetRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	existing, err := hostPortGroupNamesFromHostNetworkSystem(ctx, client, ns)
+	if err != nil {
+		return diag.Errorf("error reading existing port groups: %s", err)
+	}
+
+	// Only refresh the port groups this resource already claimed ownership
+	// of (dropping any that have since disappeared out-of-band). Never add
+	// port groups found on the host that this resource didn't create.
+	applied := schema.NewSet(schema.HashString, nil)
+	for _, name := range d.Get("applied_port_groups").(*schema.Set).List() {
+		if existing[name.(string)] {
+			applied.Add(name)
+		}
+	}
+	if err := d.Set("applied_port_groups", applied); err != nil {
+		return diag.Errorf("error saving reconciliation state: %s", err)
+	}
+
+	return nil
+}
+
+func resourceVSphereHostPortGroupSetDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client := meta.(*VSphereClient).vimClient
+	hsID := d.Get("host_system_id").(string)
+
+	ns, err := hostNetworkSystemFromHostSystemID(client, hsID)
+	if err != nil {
+		return diag.Errorf("error loading host network system: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutDelete))
+	defer cancel()
+
+	applied := schema.NewSet(schema.HashString, nil)
+	for _, name := range d.Get("applied_port_groups").(*schema.Set).List() {
+		applied.Add(name)
+	}
+
+	for _, v := range d.Get("port_group").(*schema.Set).List() {
+		name := v.(map[string]interface{})["name"].(string)
+		if !applied.Contains(name) {
+			continue
+		}
+		if err := ns.RemovePortGroup(ctx, name); err != nil {
+			return diag.Errorf("error removing port group %q: %s", name, err)
+		}
+		applied.Remove(name)
+		if err := d.Set("applied_port_groups", applied); err != nil {
+			return diag.Errorf("error saving reconciliation state: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func expandHostPortGroupSetEntries(raw []interface{}) map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{}, len(raw))
+	for _, v := range raw {
+		m := v.(map[string]interface{})
+		out[m["name"].(string)] = m
+	}
+	return out
+}
+
+func expandHostPortGroupSetEntrySpec(entry map[string]interface{}) types.HostPortGroupSpec {
+	spec := types.HostPortGroupSpec{
+		Name:        entry["name"].(string),
+		VswitchName: entry["vswitch_name"].(string),
+		VlanId:      int32(entry["vlan_id"].(int)),
+	}
+
+	active := sliceInterfacesToStrings(entry["active_nics"].([]interface{}))
+	standby := sliceInterfacesToStrings(entry["standby_nics"].([]interface{}))
+	if len(active) > 0 || len(standby) > 0 {
+		// HostNetworkPolicy.NicTeaming is nil on a zero-valued spec, same as
+		// in expandHostPortGroupSpec -- it must be allocated before its
+		// NicOrder field can be set.
+		spec.Policy.NicTeaming = &types.HostNicTeamingPolicy{}
+		spec.Policy.NicTeaming.NicOrder = &types.HostNicOrderPolicy{
+			ActiveNic:  active,
+			StandbyNic: standby,
+		}
+	}
+
+	return spec
+}
+
+func hostPortGroupNamesFromHostNetworkSystem(ctx context.Context, client *govmomi.Client, ns *object.HostNetworkSystem) (map[string]bool, error) {
+	var mns mo.HostNetworkSystem
+	if err := ns.Properties(ctx, ns.Reference(), []string{"networkInfo.portgroup"}, &mns); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]bool, len(mns.NetworkInfo.Portgroup))
+	for _, pg := range mns.NetworkInfo.Portgroup {
+		out[pg.Spec.Name] = true
+	}
+
+	log.Printf("[DEBUG] Found %d existing port groups on host network system", len(out))
+	return out, nil
+}